@@ -0,0 +1,107 @@
+package segments
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/jandedobbeleer/oh-my-posh/src/properties"
+)
+
+// Channel classifies the release stream a resolved version belongs to.
+type Channel string
+
+const (
+	ChannelStable     Channel = "stable"
+	ChannelPrerelease Channel = "prerelease"
+	ChannelNightly    Channel = "nightly"
+	ChannelDev        Channel = "dev"
+)
+
+const (
+	// LTSVersions lists the known LTS majors for a language, e.g. Node's
+	// 18/20/22 or Python's 3.11, so IsLTS can be computed without hardcoding
+	// per-language release calendars.
+	LTSVersions properties.Property = "lts_versions"
+	// ChannelIcons maps a Channel name to the glyph a segment should render for it.
+	ChannelIcons properties.Property = "channel_icons"
+)
+
+// nightlyTags and prereleaseTags are matched as substrings/prefixes of the
+// (lowercased) prerelease identifier.
+var (
+	nightlyTags    = []string{"nightly", "canary"}
+	prereleaseTags = []string{"rc", "beta", "alpha", "pre", "next"}
+)
+
+// splitVersionTags extracts the SemVer 2.0 prerelease and build-metadata
+// identifiers from a full version string, e.g. "21.0.0-nightly+sha.abcd"
+// returns ("nightly", "sha.abcd").
+func splitVersionTags(full string) (prerelease, build string) {
+	version := full
+
+	if idx := strings.Index(version, "+"); idx >= 0 {
+		build = version[idx+1:]
+		version = version[:idx]
+	}
+
+	if idx := strings.Index(version, "-"); idx >= 0 {
+		prerelease = version[idx+1:]
+	}
+
+	return prerelease, build
+}
+
+// classifyChannel determines the release channel from the prerelease and
+// build-metadata tags of a version.
+func classifyChannel(prerelease, build string) Channel {
+	tag := strings.ToLower(prerelease)
+
+	for _, nightly := range nightlyTags {
+		if strings.Contains(tag, nightly) {
+			return ChannelNightly
+		}
+	}
+
+	if strings.Contains(strings.ToLower(build), "dev") {
+		return ChannelDev
+	}
+
+	for _, pre := range prereleaseTags {
+		if strings.HasPrefix(tag, pre) {
+			return ChannelPrerelease
+		}
+	}
+
+	// Any other non-numeric-only prerelease identifier still counts as a
+	// prerelease, e.g. a custom tag like "1.2.3-snapshot".
+	if tag != "" {
+		if _, err := strconv.Atoi(tag); err != nil {
+			return ChannelPrerelease
+		}
+	}
+
+	return ChannelStable
+}
+
+func (l *language) setChannel() {
+	l.Prerelease, l.BuildMetadata = splitVersionTags(l.Full)
+	l.Channel = classifyChannel(l.Prerelease, l.BuildMetadata)
+
+	for _, version := range l.props.GetStringArray(LTSVersions, []string{}) {
+		// Some ecosystems designate LTS at the major level (Node's "18"),
+		// others at the minor level (Python's "3.11") — compare against
+		// whichever granularity the configured entry uses.
+		match := version == l.Major
+		if strings.Contains(version, ".") {
+			match = version == l.Major+"."+l.Minor
+		}
+
+		if match && l.Channel == ChannelStable {
+			l.IsLTS = true
+			break
+		}
+	}
+
+	icons := l.props.GetKeyValueMap(ChannelIcons, map[string]string{})
+	l.ChannelIcon = icons[string(l.Channel)]
+}
@@ -0,0 +1,119 @@
+package segments
+
+import (
+	"testing"
+
+	"github.com/jandedobbeleer/oh-my-posh/src/properties"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassifyChannel(t *testing.T) {
+	cases := []struct {
+		Case    string
+		Full    string
+		Channel Channel
+	}{
+		{Case: "stable", Full: "20.11.1", Channel: ChannelStable},
+		{Case: "rc", Full: "21.0.0-rc.1", Channel: ChannelPrerelease},
+		{Case: "beta", Full: "3.13.0-beta2", Channel: ChannelPrerelease},
+		{Case: "nightly", Full: "21.0.0-nightly", Channel: ChannelNightly},
+		{Case: "canary", Full: "18.3.0-canary.4", Channel: ChannelNightly},
+		{Case: "dev build metadata", Full: "1.2.3+dev", Channel: ChannelDev},
+		{Case: "custom prerelease tag", Full: "1.2.3-snapshot", Channel: ChannelPrerelease},
+	}
+
+	for _, tc := range cases {
+		prerelease, build := splitVersionTags(tc.Full)
+		assert.Equal(t, tc.Channel, classifyChannel(prerelease, build), tc.Case)
+	}
+}
+
+func TestLanguageChannelAndLTS(t *testing.T) {
+	props := properties.Map{
+		LTSVersions: []string{"18", "20", "22"},
+		ChannelIcons: map[string]string{
+			string(ChannelStable):     "✓",
+			string(ChannelNightly):    "⬢",
+			string(ChannelPrerelease): "↑",
+		},
+	}
+
+	args := &languageArgs{
+		commands: []*cmd{
+			{
+				executable: "uni",
+				args:       []string{"--version"},
+				regex:      `(?P<version>((?P<major>[0-9]+)\.(?P<minor>[0-9]+)\.(?P<patch>[0-9]+)(?:-(?P<prerelease>[a-zA-Z0-9.]+))?))`,
+			},
+		},
+		extensions:        []string{uni},
+		enabledExtensions: []string{uni},
+		enabledCommands:   []string{"uni"},
+		version:           "20.11.1",
+		properties:        props,
+	}
+	lang := bootStrapLanguageTest(args)
+	assert.True(t, lang.Enabled())
+	assert.Equal(t, ChannelStable, lang.Channel)
+	assert.True(t, lang.IsLTS)
+	assert.Equal(t, "✓", lang.ChannelIcon)
+
+	nightlyArgs := &languageArgs{
+		commands: []*cmd{
+			{
+				executable: "uni",
+				args:       []string{"--version"},
+				regex:      `(?P<version>((?P<major>[0-9]+)\.(?P<minor>[0-9]+)\.(?P<patch>[0-9]+)-(?P<prerelease>[a-zA-Z0-9.]+)))`,
+			},
+		},
+		extensions:        []string{uni},
+		enabledExtensions: []string{uni},
+		enabledCommands:   []string{"uni"},
+		version:           "21.0.0-nightly",
+		properties:        props,
+	}
+	nightly := bootStrapLanguageTest(nightlyArgs)
+	assert.True(t, nightly.Enabled())
+	assert.Equal(t, ChannelNightly, nightly.Channel)
+	assert.False(t, nightly.IsLTS)
+	assert.Equal(t, "⬢", nightly.ChannelIcon)
+}
+
+// TestLanguageMinorGranularLTS covers ecosystems (e.g. Python) whose LTS
+// designation is a major.minor pair rather than a bare major.
+func TestLanguageMinorGranularLTS(t *testing.T) {
+	props := properties.Map{
+		LTSVersions: []string{"3.11"},
+	}
+
+	args := &languageArgs{
+		commands: []*cmd{
+			{
+				executable: "uni",
+				args:       []string{"--version"},
+				regex:      `(?P<version>((?P<major>[0-9]+)\.(?P<minor>[0-9]+)\.(?P<patch>[0-9]+)))`,
+			},
+		},
+		extensions:        []string{uni},
+		enabledExtensions: []string{uni},
+		enabledCommands:   []string{"uni"},
+		version:           "3.11.4",
+		properties:        props,
+	}
+	lang := bootStrapLanguageTest(args)
+	assert.True(t, lang.Enabled())
+	assert.True(t, lang.IsLTS, "3.11 is configured as an LTS minor, not just a major")
+
+	nonLTSArgs := &languageArgs{
+		commands:          args.commands,
+		extensions:        []string{uni},
+		enabledExtensions: []string{uni},
+		enabledCommands:   []string{"uni"},
+		version:           "3.12.0",
+		properties:        props,
+	}
+	nonLTS := bootStrapLanguageTest(nonLTSArgs)
+	assert.True(t, nonLTS.Enabled())
+	assert.False(t, nonLTS.IsLTS, "3.12 isn't in lts_versions")
+}
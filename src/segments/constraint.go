@@ -0,0 +1,372 @@
+package segments
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ConstraintKind classifies the shape of a parsed Constraint so templates
+// can render it without having to inspect the raw string themselves.
+type ConstraintKind string
+
+const (
+	// ConstraintExact is a single pinned version, e.g. "1.2.3".
+	ConstraintExact ConstraintKind = "exact"
+	// ConstraintRange is a disjunction of comparator ranges, e.g. "^1.2.3" or ">=1.0 <2.0".
+	ConstraintRange ConstraintKind = "range"
+	// ConstraintAny matches every version, e.g. "*", "x" or "lts/*".
+	ConstraintAny ConstraintKind = "any"
+)
+
+// semVersion is a concrete major.minor.patch[-pre][+build] triple. Parsed
+// Constraint comparators always hold a concrete semVersion; wildcards such
+// as "1.x" are expanded into a range of concrete comparators at parse time.
+type semVersion struct {
+	major, minor, patch int
+	prerelease          string
+}
+
+func (v *semVersion) less(other *semVersion) bool {
+	if v.major != other.major {
+		return v.major < other.major
+	}
+
+	if v.minor != other.minor {
+		return v.minor < other.minor
+	}
+
+	if v.patch != other.patch {
+		return v.patch < other.patch
+	}
+
+	// A version without a prerelease tag outranks one with a tag for the
+	// same major.minor.patch, per SemVer 2.0 precedence rules.
+	if v.prerelease == other.prerelease {
+		return false
+	}
+
+	if v.prerelease == "" {
+		return false
+	}
+
+	if other.prerelease == "" {
+		return true
+	}
+
+	return v.prerelease < other.prerelease
+}
+
+func (v *semVersion) equal(other *semVersion) bool {
+	return v.major == other.major && v.minor == other.minor && v.patch == other.patch && v.prerelease == other.prerelease
+}
+
+func (v *semVersion) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.major, v.minor, v.patch)
+	if v.prerelease != "" {
+		s += "-" + v.prerelease
+	}
+
+	return s
+}
+
+// parseSemVersion parses a concrete version string, ignoring any leading
+// "v" and trailing build metadata (it has no bearing on precedence).
+func parseSemVersion(raw string) (*semVersion, error) {
+	raw = strings.TrimPrefix(strings.TrimSpace(raw), "v")
+
+	if raw == "" {
+		return nil, fmt.Errorf("empty version")
+	}
+
+	if idx := strings.Index(raw, "+"); idx >= 0 {
+		raw = raw[:idx]
+	}
+
+	main := raw
+	prerelease := ""
+
+	if idx := strings.Index(raw, "-"); idx >= 0 {
+		main = raw[:idx]
+		prerelease = raw[idx+1:]
+	}
+
+	parts := strings.Split(main, ".")
+
+	nums := make([]int, 3)
+
+	for i := range nums {
+		if i >= len(parts) || parts[i] == "" {
+			continue
+		}
+
+		n, err := strconv.Atoi(parts[i])
+		if err != nil {
+			return nil, fmt.Errorf("invalid version segment %q in %q", parts[i], raw)
+		}
+
+		nums[i] = n
+	}
+
+	return &semVersion{major: nums[0], minor: nums[1], patch: nums[2], prerelease: prerelease}, nil
+}
+
+// comparator is a single "<op> version" test, e.g. ">= 1.2.3".
+type comparator struct {
+	operator string
+	version  *semVersion
+}
+
+func (c *comparator) satisfies(v *semVersion) bool {
+	switch c.operator {
+	case "=":
+		return v.equal(c.version)
+	case ">":
+		return c.version.less(v)
+	case ">=":
+		return c.version.less(v) || v.equal(c.version)
+	case "<":
+		return v.less(c.version)
+	case "<=":
+		return v.less(c.version) || v.equal(c.version)
+	default:
+		return false
+	}
+}
+
+// Constraint is a disjunction ("||") of conjunctions ("a b c") of
+// comparators, the same shape node-semver and friends use.
+type Constraint struct {
+	Kind ConstraintKind
+	raw  string
+	sets [][]comparator
+}
+
+// String renders the human-readable constraint as it was written in the
+// project's version/lockfile, e.g. "^1.2.3" or ">=1.0.0 <2.0.0".
+func (c *Constraint) String() string {
+	return c.raw
+}
+
+// Satisfies reports whether the given concrete version (e.g. "1.3.307")
+// fulfils the constraint.
+func (c *Constraint) Satisfies(version string) bool {
+	if c.Kind == ConstraintAny {
+		return true
+	}
+
+	v, err := parseSemVersion(version)
+	if err != nil {
+		return false
+	}
+
+	for _, set := range c.sets {
+		match := true
+
+		for _, comp := range set {
+			if !comp.satisfies(v) {
+				match = false
+				break
+			}
+		}
+
+		if match {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ParseConstraint turns a raw version/constraint string — as found in a
+// pin file, a package.json "engines" entry, or an asdf .tool-versions
+// line — into a Constraint. Recognised syntax: "*"/"x" (any), "^1.2.3",
+// "~1.2.3", bare comparators ("<", "<=", ">", ">=", "="), wildcard
+// versions ("1.x", "1.2.x"), space-separated conjunctions and "||"
+// disjunctions, and plain pinned versions ("1.2.3").
+func ParseConstraint(raw string) (*Constraint, error) {
+	trimmed := strings.TrimSpace(raw)
+
+	if trimmed == "" || trimmed == "*" || trimmed == "x" || trimmed == "X" || strings.HasPrefix(trimmed, "lts") {
+		return &Constraint{Kind: ConstraintAny, raw: trimmed}, nil
+	}
+
+	var sets [][]comparator
+
+	kind := ConstraintExact
+
+	for _, disjunct := range strings.Split(trimmed, "||") {
+		fields := strings.Fields(disjunct)
+		if len(fields) == 0 {
+			continue
+		}
+
+		if len(fields) > 1 {
+			kind = ConstraintRange
+		}
+
+		var set []comparator
+
+		for _, field := range fields {
+			comparators, err := parseConstraintTerm(field)
+			if err != nil {
+				return nil, err
+			}
+
+			// A lone comparator with no upper bound (">=1.2.3") is still an
+			// open-ended range, not a single pinned version.
+			if len(comparators) > 1 || comparators[0].operator != "=" {
+				kind = ConstraintRange
+			}
+
+			set = append(set, comparators...)
+		}
+
+		sets = append(sets, set)
+	}
+
+	if len(sets) > 1 {
+		kind = ConstraintRange
+	}
+
+	return &Constraint{Kind: kind, raw: trimmed, sets: sets}, nil
+}
+
+// parseConstraintTerm parses a single space-delimited term of a
+// constraint, expanding "^"/"~" prefixes and "x" wildcards into their
+// equivalent range of comparators.
+func parseConstraintTerm(term string) ([]comparator, error) {
+	switch {
+	case strings.HasPrefix(term, "^"):
+		return expandCaret(term[1:])
+	case strings.HasPrefix(term, "~="):
+		return expandPipCompatible(term[2:])
+	case strings.HasPrefix(term, "~"):
+		return expandTilde(term[1:])
+	case strings.HasPrefix(term, ">="), strings.HasPrefix(term, "<="):
+		op := term[:2]
+		v, err := parseSemVersion(term[2:])
+		if err != nil {
+			return nil, err
+		}
+
+		return []comparator{{operator: op, version: v}}, nil
+	case strings.HasPrefix(term, ">"), strings.HasPrefix(term, "<"), strings.HasPrefix(term, "="):
+		op := term[:1]
+		v, err := parseSemVersion(term[1:])
+		if err != nil {
+			return nil, err
+		}
+
+		return []comparator{{operator: op, version: v}}, nil
+	case strings.ContainsAny(term, "xX*"):
+		return expandWildcard(term)
+	default:
+		v, err := parseSemVersion(term)
+		if err != nil {
+			return nil, err
+		}
+
+		return []comparator{{operator: "=", version: v}}, nil
+	}
+}
+
+// expandCaret implements "^A.B.C" => ">=A.B.C <A+1.0.0" for A>0, and the
+// narrower npm semantics for a leading zero major/minor.
+func expandCaret(raw string) ([]comparator, error) {
+	v, err := parseSemVersion(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	upper := &semVersion{major: v.major + 1}
+
+	switch {
+	case v.major == 0 && v.minor == 0:
+		upper = &semVersion{major: 0, minor: 0, patch: v.patch + 1}
+	case v.major == 0:
+		upper = &semVersion{major: 0, minor: v.minor + 1}
+	}
+
+	return []comparator{
+		{operator: ">=", version: v},
+		{operator: "<", version: upper},
+	}, nil
+}
+
+// expandTilde implements npm's "~A.B.C" => ">=A.B.C <A.B+1.0".
+func expandTilde(raw string) ([]comparator, error) {
+	v, err := parseSemVersion(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	upper := &semVersion{major: v.major, minor: v.minor + 1}
+
+	return []comparator{
+		{operator: ">=", version: v},
+		{operator: "<", version: upper},
+	}, nil
+}
+
+// expandPipCompatible implements PEP 440's "~=" compatible-release operator,
+// which is NOT the same as npm's tilde: a two-segment specifier ("~=1.4")
+// bumps the major ">=1.4,<2.0", while a three-segment one ("~=1.4.2") bumps
+// the minor ">=1.4.2,<1.5.0" like npm's "~".
+func expandPipCompatible(raw string) ([]comparator, error) {
+	v, err := parseSemVersion(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	upper := &semVersion{major: v.major + 1}
+	if len(strings.Split(raw, ".")) > 2 {
+		upper = &semVersion{major: v.major, minor: v.minor + 1}
+	}
+
+	return []comparator{
+		{operator: ">=", version: v},
+		{operator: "<", version: upper},
+	}, nil
+}
+
+// expandWildcard implements "1.x" => ">=1.0.0 <2.0.0" and "1.2.x" =>
+// ">=1.2.0 <1.3.0".
+func expandWildcard(raw string) ([]comparator, error) {
+	parts := strings.Split(raw, ".")
+
+	isWildcard := func(s string) bool {
+		return s == "" || s == "x" || s == "X" || s == "*"
+	}
+
+	if len(parts) >= 2 && !isWildcard(parts[0]) && isWildcard(parts[1]) {
+		major, err := strconv.Atoi(parts[0])
+		if err != nil {
+			return nil, err
+		}
+
+		return []comparator{
+			{operator: ">=", version: &semVersion{major: major}},
+			{operator: "<", version: &semVersion{major: major + 1}},
+		}, nil
+	}
+
+	if len(parts) >= 3 && !isWildcard(parts[1]) && isWildcard(parts[2]) {
+		major, err := strconv.Atoi(parts[0])
+		if err != nil {
+			return nil, err
+		}
+
+		minor, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return nil, err
+		}
+
+		return []comparator{
+			{operator: ">=", version: &semVersion{major: major, minor: minor}},
+			{operator: "<", version: &semVersion{major: major, minor: minor + 1}},
+		}, nil
+	}
+
+	return nil, fmt.Errorf("unsupported wildcard constraint %q", raw)
+}
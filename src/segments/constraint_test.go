@@ -0,0 +1,47 @@
+package segments
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseConstraint(t *testing.T) {
+	cases := []struct {
+		Case       string
+		Constraint string
+		Kind       ConstraintKind
+		Matches    string
+		Mismatches string
+	}{
+		{Case: "pinned asdf .tool-versions entry", Constraint: "3.11.4", Kind: ConstraintExact, Matches: "3.11.4", Mismatches: "3.11.5"},
+		{Case: ".python-version pin", Constraint: "3.11.4", Kind: ConstraintExact, Matches: "3.11.4", Mismatches: "3.12.0"},
+		{Case: ".nvmrc lts alias", Constraint: "lts/*", Kind: ConstraintAny, Matches: "20.11.1", Mismatches: ""},
+		{Case: "package.json engines.node caret", Constraint: "^18.0.0", Kind: ConstraintRange, Matches: "18.19.0", Mismatches: "19.0.0"},
+		{Case: "package.json engines.node tilde", Constraint: "~1.4.0", Kind: ConstraintRange, Matches: "1.4.9", Mismatches: "1.5.0"},
+		{Case: "pip style approximate, two segments bumps the major", Constraint: "~=1.4", Kind: ConstraintRange, Matches: "1.9.9", Mismatches: "2.0.0"},
+		{Case: "pip style approximate, three segments bumps the minor", Constraint: "~=1.4.2", Kind: ConstraintRange, Matches: "1.4.9", Mismatches: "1.5.0"},
+		{Case: "explicit bounds", Constraint: ">=1.0 <2.0", Kind: ConstraintRange, Matches: "1.9.9", Mismatches: "2.0.0"},
+		{Case: "bare lower bound has no pin", Constraint: ">=1.2.3", Kind: ConstraintRange, Matches: "9.9.9", Mismatches: "1.2.2"},
+		{Case: "npm-style x range", Constraint: "1.x", Kind: ConstraintRange, Matches: "1.9.9", Mismatches: "2.0.0"},
+		{Case: "any version", Constraint: "*", Kind: ConstraintAny, Matches: "9.9.9", Mismatches: ""},
+	}
+
+	for _, tc := range cases {
+		constraint, err := ParseConstraint(tc.Constraint)
+
+		assert.NoError(t, err, tc.Case)
+		assert.Equal(t, tc.Kind, constraint.Kind, tc.Case)
+		assert.Equal(t, tc.Constraint, constraint.String(), tc.Case)
+		assert.True(t, constraint.Satisfies(tc.Matches), tc.Case)
+
+		if tc.Mismatches != "" {
+			assert.False(t, constraint.Satisfies(tc.Mismatches), tc.Case)
+		}
+	}
+}
+
+func TestParseConstraintInvalid(t *testing.T) {
+	_, err := ParseConstraint("^not-a-version")
+	assert.Error(t, err)
+}
@@ -0,0 +1,232 @@
+package segments
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jandedobbeleer/oh-my-posh/src/properties"
+	"github.com/jandedobbeleer/oh-my-posh/src/regex"
+	"github.com/jandedobbeleer/oh-my-posh/src/runtime"
+	"github.com/jandedobbeleer/oh-my-posh/src/template"
+)
+
+const (
+	// MissingCommandText shows text instead of missing command
+	MissingCommandText properties.Property = "missing_command_text"
+
+	noVersion = "no version available"
+)
+
+// matchesVersionFile resolves the version constraint (if any) a project pins
+// for a language, e.g. by reading .nvmrc or .python-version. The returned
+// Constraint is nil when no version file was found.
+type matchesVersionFile func() (constraint *Constraint, found bool)
+
+// cmd is a command that can be used to get the version of a language.
+type cmd struct {
+	executable         string
+	args               []string
+	regex              string
+	versionURLTemplate string
+}
+
+type language struct {
+	base
+
+	Executable string
+	Full       string
+	Major      string
+	Minor      string
+	Patch      string
+
+	Error string
+
+	URL string
+
+	// Expected is the human readable version constraint pinned by the
+	// project (a version file, a lockfile, an engines field, ...).
+	Expected string
+	// Mismatch is true when the resolved version does not satisfy Expected.
+	Mismatch bool
+	// Satisfies mirrors !Mismatch once a constraint was found; it exists
+	// so templates don't need to negate a field.
+	Satisfies bool
+	// ConstraintKind tells templates how to render Expected: "exact",
+	// "range" or "any".
+	ConstraintKind ConstraintKind
+
+	// Prerelease and BuildMetadata are the SemVer 2.0 tags parsed out of
+	// Full, e.g. "rc.1" and "sha.5114f85" for "1.2.3-rc.1+sha.5114f85".
+	Prerelease    string
+	BuildMetadata string
+	// Channel classifies the release stream: stable, prerelease, nightly or dev.
+	Channel Channel
+	// IsLTS is true for a stable-channel version whose Major (or Major.Minor,
+	// for entries like "3.11") matches one of the lts_versions property entries.
+	IsLTS bool
+	// ChannelIcon is the glyph configured for Channel via the channel_icons property.
+	ChannelIcon string
+
+	// Packages holds the resolved versions of dependencies looked up via
+	// resolvePackage, keyed by package name, e.g. Packages["django"].
+	Packages map[string]string
+
+	exitCode int
+
+	matchesVersionFile matchesVersionFile
+
+	// toolVersionName is the asdf/mise plugin name (e.g. "nodejs", "python")
+	// used to look up a pin via the toolversions subsystem when the segment
+	// doesn't set its own matchesVersionFile.
+	toolVersionName string
+
+	// ToolVersionsFile and ToolVersionsManager describe which file and
+	// manager produced Expected when it came from the toolversions
+	// subsystem rather than a segment-specific matchesVersionFile.
+	ToolVersionsFile    string
+	ToolVersionsManager string
+
+	extensions         []string
+	commands           []*cmd
+	versionURLTemplate string
+}
+
+func (l *language) Init(props properties.Properties, env runtime.Environment) {
+	l.base.Init(props, env)
+}
+
+func (l *language) Enabled() bool {
+	if l.env.Pwd() == l.env.Home() && !l.props.GetBool(HomeEnabled, false) {
+		return false
+	}
+
+	if !l.hasFiles() {
+		return false
+	}
+
+	if !l.props.GetBool(properties.FetchVersion, true) {
+		return true
+	}
+
+	l.setVersion()
+
+	return true
+}
+
+func (l *language) hasFiles() bool {
+	if len(l.extensions) == 0 {
+		return true
+	}
+
+	for _, extension := range l.extensions {
+		if l.env.HasFiles(extension) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (l *language) setVersion() {
+	var enabled []*cmd
+
+	for _, c := range l.commands {
+		if l.env.HasCommand(c.executable) {
+			enabled = append(enabled, c)
+		}
+	}
+
+	if len(enabled) == 0 {
+		l.Error = l.props.GetString(MissingCommandText, noVersion)
+		return
+	}
+
+	results := l.runCommands(enabled)
+
+	// Commands run concurrently, but precedence is unchanged: the first
+	// enabled command, in configured order, is the one whose result counts.
+	command := enabled[0]
+	result := results[command]
+
+	if result.err != nil {
+		var cmdErr *runtime.CommandError
+		if errors.As(result.err, &cmdErr) {
+			l.exitCode = cmdErr.ExitCode
+		}
+
+		l.Error = fmt.Sprintf("err executing %s with %v", command.executable, command.args)
+
+		return
+	}
+
+	values, ok := regex.FindNamedRegexMatch(command.regex, result.output)
+	if !ok {
+		l.Error = fmt.Sprintf("err parsing info from %s with %s", command.executable, result.output)
+		return
+	}
+
+	l.Executable = command.executable
+	l.Full = values["version"]
+	l.Major = values["major"]
+	l.Minor = values["minor"]
+	l.Patch = values["patch"]
+
+	l.setURL(command)
+	l.setChannel()
+	l.setConstraint()
+}
+
+func (l *language) setURL(command *cmd) {
+	urlTemplate := l.versionURLTemplate
+
+	if len(command.versionURLTemplate) > 0 {
+		urlTemplate = command.versionURLTemplate
+	}
+
+	if propTemplate := l.props.GetString(properties.VersionURLTemplate, ""); len(propTemplate) > 0 {
+		urlTemplate = propTemplate
+	}
+
+	if len(urlTemplate) == 0 {
+		return
+	}
+
+	tmpl := &template.Text{
+		Template: urlTemplate,
+		Context:  l,
+	}
+
+	url, err := tmpl.Render()
+	if err != nil {
+		return
+	}
+
+	l.URL = url
+}
+
+func (l *language) setConstraint() {
+	if l.matchesVersionFile == nil && l.toolVersionName != "" {
+		l.matchesVersionFile = l.matchesToolVersions(l.toolVersionName)
+	}
+
+	if l.matchesVersionFile == nil {
+		return
+	}
+
+	constraint, found := l.matchesVersionFile()
+	if !found || constraint == nil {
+		return
+	}
+
+	l.Expected = constraint.String()
+	l.ConstraintKind = constraint.Kind
+	l.Satisfies = constraint.Satisfies(l.Full)
+	l.Mismatch = !l.Satisfies
+}
+
+// nodePackageVersion looks up the resolved version of a node_modules
+// package, reading it straight from its package.json. Kept for backwards
+// compatibility; it now delegates to the node PackageVersionResolver.
+func (l *language) nodePackageVersion(name string) (string, error) {
+	return l.resolvePackage(nodePackageResolver{}, name)
+}
@@ -0,0 +1,107 @@
+package segments
+
+import (
+	goruntime "runtime"
+	"strings"
+	"sync"
+
+	"github.com/jandedobbeleer/oh-my-posh/src/properties"
+)
+
+// MaxParallelCommands bounds how many version commands a language segment
+// dispatches at once; it defaults to the number of available CPUs.
+const MaxParallelCommands properties.Property = "max_parallel_commands"
+
+type commandResult struct {
+	output string
+	err    error
+}
+
+// versionCommandCache memoizes a version command's raw output for the
+// lifetime of this process, so segments sharing an executable (e.g.
+// "python --version", used by the Python, Poetry and Pyenv segments) reuse
+// the stored result instead of spawning it again. Lookups of the same key
+// that miss at the same moment each run the command; only lookups made after
+// a result is stored skip the spawn. oh-my-posh renders a prompt as a fresh process
+// invocation, so a process-lifetime cache IS a per-render cache — unlike
+// env.Cache(), which is durable across renders and would leak a version
+// from before e.g. `nvm use` into the next one for as long as its TTL held.
+var (
+	versionCommandCacheMu sync.Mutex
+	versionCommandCache   = map[string]commandResult{}
+)
+
+// resetVersionCommandCache clears the process-lifetime cache. Tests call
+// this so unrelated test cases sharing a generic executable/cwd combo in
+// this package's test suite don't see each other's cached results.
+func resetVersionCommandCache() {
+	versionCommandCacheMu.Lock()
+	versionCommandCache = map[string]commandResult{}
+	versionCommandCacheMu.Unlock()
+}
+
+// runCommands runs every command concurrently, bounded by
+// max_parallel_commands, and returns each one's raw output/error keyed by
+// the *cmd pointer it came from.
+func (l *language) runCommands(commands []*cmd) map[*cmd]commandResult {
+	results := make(map[*cmd]commandResult, len(commands))
+
+	maxParallel := l.props.GetInt(MaxParallelCommands, goruntime.NumCPU())
+	if maxParallel < 1 {
+		maxParallel = 1
+	}
+
+	semaphore := make(chan struct{}, maxParallel)
+
+	var (
+		wg sync.WaitGroup
+		mu sync.Mutex
+	)
+
+	for _, c := range commands {
+		wg.Add(1)
+
+		go func(c *cmd) {
+			defer wg.Done()
+
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			output, err := l.cachedRunCommand(c)
+
+			mu.Lock()
+			results[c] = commandResult{output: output, err: err}
+			mu.Unlock()
+		}(c)
+	}
+
+	wg.Wait()
+
+	return results
+}
+
+// cachedRunCommand runs c.executable, reusing a cached result for the same
+// executable + args + working directory within this render instead of
+// spawning the subprocess again.
+func (l *language) cachedRunCommand(c *cmd) (string, error) {
+	key := versionCommandCacheKey(c, l.env.Pwd())
+
+	versionCommandCacheMu.Lock()
+	if cached, ok := versionCommandCache[key]; ok {
+		versionCommandCacheMu.Unlock()
+		return cached.output, cached.err
+	}
+	versionCommandCacheMu.Unlock()
+
+	output, err := l.env.RunCommand(c.executable, c.args)
+
+	versionCommandCacheMu.Lock()
+	versionCommandCache[key] = commandResult{output: output, err: err}
+	versionCommandCacheMu.Unlock()
+
+	return output, err
+}
+
+func versionCommandCacheKey(c *cmd, cwd string) string {
+	return c.executable + " " + strings.Join(c.args, " ") + " " + cwd
+}
@@ -0,0 +1,116 @@
+package segments
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jandedobbeleer/oh-my-posh/src/properties"
+	"github.com/jandedobbeleer/oh-my-posh/src/runtime/mock"
+
+	"github.com/stretchr/testify/assert"
+	mock_ "github.com/stretchr/testify/mock"
+)
+
+func TestCachedRunCommandHitSkipsRunCommand(t *testing.T) {
+	resetVersionCommandCache()
+
+	env := new(mock.Environment)
+	env.On("Pwd").Return("/usr/home/project")
+	env.On("RunCommand", "python", []string{"--version"}).Return(universion, nil).Once()
+
+	l := &language{}
+	l.Init(properties.Map{}, env)
+
+	c := &cmd{executable: "python", args: []string{"--version"}}
+
+	first, err := l.cachedRunCommand(c)
+	assert.NoError(t, err)
+	assert.Equal(t, universion, first)
+
+	second, err := l.cachedRunCommand(c)
+	assert.NoError(t, err)
+	assert.Equal(t, universion, second)
+
+	env.AssertNumberOfCalls(t, "RunCommand", 1)
+}
+
+func TestCachedRunCommandPopulatesCacheOnMiss(t *testing.T) {
+	resetVersionCommandCache()
+
+	env := new(mock.Environment)
+	env.On("Pwd").Return("/usr/home/project")
+	env.On("RunCommand", "python", []string{"--version"}).Return(universion, nil).Once()
+
+	l := &language{}
+	l.Init(properties.Map{}, env)
+
+	output, err := l.cachedRunCommand(&cmd{executable: "python", args: []string{"--version"}})
+
+	assert.NoError(t, err)
+	assert.Equal(t, universion, output)
+	env.AssertNumberOfCalls(t, "RunCommand", 1)
+}
+
+// TestRunCommandsSharedExecutableHitsCacheOnce drives two language segments
+// that both configure "python --version": once the first segment populates
+// the process-lifetime cache, the second must not spawn the subprocess
+// again. Run with -race to confirm runCommands' worker pool doesn't
+// corrupt the shared results map.
+func TestRunCommandsSharedExecutableHitsCacheOnce(t *testing.T) {
+	resetVersionCommandCache()
+
+	env := new(mock.Environment)
+	env.On("Pwd").Return("/usr/home/project")
+	env.On("RunCommand", "python", []string{"--version"}).Return(universion, nil).Once()
+
+	pythonCmd := &cmd{executable: "python", args: []string{"--version"}, regex: "(?P<version>.*)"}
+
+	first := &language{}
+	first.Init(properties.Map{}, env)
+	firstResults := first.runCommands([]*cmd{pythonCmd})
+
+	second := &language{}
+	second.Init(properties.Map{}, env)
+	secondResults := second.runCommands([]*cmd{pythonCmd})
+
+	assert.Equal(t, universion, firstResults[pythonCmd].output)
+	assert.Equal(t, universion, secondResults[pythonCmd].output)
+	env.AssertNumberOfCalls(t, "RunCommand", 1)
+}
+
+func TestRunCommandsRespectsMaxParallelCommands(t *testing.T) {
+	resetVersionCommandCache()
+
+	env := new(mock.Environment)
+	env.On("Pwd").Return("/usr/home/project")
+	var inFlight, maxInFlight int32
+	track := func(mock_.Arguments) {
+		n := atomic.AddInt32(&inFlight, 1)
+		for {
+			peak := atomic.LoadInt32(&maxInFlight)
+			if n <= peak || atomic.CompareAndSwapInt32(&maxInFlight, peak, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		atomic.AddInt32(&inFlight, -1)
+	}
+
+	env.On("RunCommand", "uni", []string{"--version"}).Return(universion, nil).Run(track)
+	env.On("RunCommand", "corn", []string{"--version"}).Return(universion, nil).Run(track)
+
+	l := &language{}
+	l.Init(properties.Map{MaxParallelCommands: 1}, env)
+
+	commands := []*cmd{
+		{executable: "uni", args: []string{"--version"}},
+		{executable: "corn", args: []string{"--version"}},
+	}
+
+	results := l.runCommands(commands)
+
+	assert.Len(t, results, 2)
+	env.AssertNumberOfCalls(t, "RunCommand", 2)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&maxInFlight), "max_parallel_commands 1 lets only one command run at a time")
+}
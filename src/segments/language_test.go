@@ -38,6 +38,11 @@ func (l *languageArgs) hasvalue(value string, list []string) bool {
 }
 
 func bootStrapLanguageTest(args *languageArgs) *language {
+	// Each test configures its own RunCommand behavior for potentially the
+	// same executable/cwd combo another test already used; the
+	// process-lifetime version command cache must not leak between them.
+	resetVersionCommandCache()
+
 	env := new(mock.Environment)
 
 	for _, command := range args.commands {
@@ -165,7 +170,7 @@ func TestLanguageEnabledOneExtensionFound(t *testing.T) {
 }
 
 func TestLanguageEnabledMismatch(t *testing.T) {
-	expectedVersion := "1.2.009"
+	expectedVersion := "1.2.9"
 
 	args := &languageArgs{
 		commands: []*cmd{
@@ -179,14 +184,17 @@ func TestLanguageEnabledMismatch(t *testing.T) {
 		enabledExtensions: []string{uni},
 		enabledCommands:   []string{"unicorn"},
 		version:           universion,
-		matchesVersionFile: func() (string, bool) {
-			return expectedVersion, false
+		matchesVersionFile: func() (*Constraint, bool) {
+			constraint, _ := ParseConstraint(expectedVersion)
+			return constraint, true
 		},
 	}
 	lang := bootStrapLanguageTest(args)
 	assert.True(t, lang.Enabled())
-	assert.Equal(t, expectedVersion, lang.Expected, "the expected unicorn version is 1.2.009")
+	assert.Equal(t, expectedVersion, lang.Expected, "the expected unicorn version is 1.2.9")
+	assert.Equal(t, ConstraintExact, lang.ConstraintKind)
 	assert.True(t, lang.Mismatch, "we require a different version of unicorn")
+	assert.False(t, lang.Satisfies)
 }
 
 func TestLanguageDisabledInHome(t *testing.T) {
@@ -577,6 +585,8 @@ func TestNodePackageVersion(t *testing.T) {
 		path := filepath.Join("posh", "node_modules", "nx")
 		env.On("HasFilesInDir", path, "package.json").Return(!tc.NoFiles)
 		env.On("FileContent", filepath.Join(path, "package.json")).Return(tc.PackageJSON)
+		env.On("HasFiles", "yarn.lock").Return(false)
+		env.On("HasFiles", "pnpm-lock.yaml").Return(false)
 
 		a := &language{}
 		a.Init(properties.Map{}, env)
@@ -0,0 +1,404 @@
+package segments
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/jandedobbeleer/oh-my-posh/src/runtime"
+)
+
+// PackageVersionResolver resolves the version of a single dependency from
+// whichever ecosystem-specific lockfile or manifest a project uses, e.g.
+// Cargo.lock for Rust or poetry.lock for Python.
+type PackageVersionResolver interface {
+	Version(env runtime.Environment, packageName string) (string, error)
+}
+
+// resolvePackage runs resolver for packageName and, on success, caches the
+// result on the segment so the .Packages.<name> template field works.
+func (l *language) resolvePackage(resolver PackageVersionResolver, packageName string) (string, error) {
+	version, err := resolver.Version(l.env, packageName)
+	if err != nil {
+		return "", err
+	}
+
+	if l.Packages == nil {
+		l.Packages = make(map[string]string)
+	}
+
+	l.Packages[packageName] = version
+
+	return version, nil
+}
+
+// nodePackageResolver resolves an installed dependency's version, preferring
+// the installed node_modules/<pkg>/package.json and falling back to whatever
+// lockfile the project's package manager produced.
+type nodePackageResolver struct{}
+
+func (nodePackageResolver) Version(env runtime.Environment, packageName string) (string, error) {
+	if version, err := nodeModulesPackageVersion(env, packageName); err == nil {
+		return version, nil
+	}
+
+	if version, err := nodeYarnLockVersion(env, packageName); err == nil {
+		return version, nil
+	}
+
+	return nodePnpmLockVersion(env, packageName)
+}
+
+func nodeModulesPackageVersion(env runtime.Environment, packageName string) (string, error) {
+	path := filepath.Join(env.Pwd(), "node_modules", packageName)
+
+	if !env.HasFilesInDir(path, "package.json") {
+		return "", fmt.Errorf("unable to find package.json for %s", packageName)
+	}
+
+	content := env.FileContent(filepath.Join(path, "package.json"))
+
+	var data struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+	}
+
+	if err := json.Unmarshal([]byte(content), &data); err != nil {
+		return "", err
+	}
+
+	return data.Version, nil
+}
+
+// yarnLockEntryRe matches a yarn.lock v1 block's "version" field once the
+// block's header (which may list several semver ranges) is known to contain
+// the package name.
+var yarnLockEntryRe = regexp.MustCompile(`(?m)^\s*version\s+"([^"]+)"`)
+
+func nodeYarnLockVersion(env runtime.Environment, packageName string) (string, error) {
+	if !env.HasFiles("yarn.lock") {
+		return "", fmt.Errorf("yarn.lock not found")
+	}
+
+	content := env.FileContent(filepath.Join(env.Pwd(), "yarn.lock"))
+
+	headerRe, err := regexp.Compile(`(?m)^"?` + regexp.QuoteMeta(packageName) + `@[^\n]*:\s*$`)
+	if err != nil {
+		return "", err
+	}
+
+	loc := headerRe.FindStringIndex(content)
+	if loc == nil {
+		return "", fmt.Errorf("package %s not found in yarn.lock", packageName)
+	}
+
+	block := content[loc[1]:]
+	if next := strings.Index(block, "\n\n"); next >= 0 {
+		block = block[:next]
+	}
+
+	match := yarnLockEntryRe.FindStringSubmatch(block)
+	if match == nil {
+		return "", fmt.Errorf("package %s has no version in yarn.lock", packageName)
+	}
+
+	return match[1], nil
+}
+
+// nodePnpmLockVersion matches both pnpm-lock.yaml v6 ("/name@1.2.3:") and v9+
+// ("name@1.2.3:") package-key styles.
+func nodePnpmLockVersion(env runtime.Environment, packageName string) (string, error) {
+	if !env.HasFiles("pnpm-lock.yaml") {
+		return "", fmt.Errorf("pnpm-lock.yaml not found")
+	}
+
+	content := env.FileContent(filepath.Join(env.Pwd(), "pnpm-lock.yaml"))
+
+	re, err := regexp.Compile(`(?m)^\s*/?` + regexp.QuoteMeta(packageName) + `@([^\s:(]+)`)
+	if err != nil {
+		return "", err
+	}
+
+	match := re.FindStringSubmatch(content)
+	if match == nil {
+		return "", fmt.Errorf("package %s not found in pnpm-lock.yaml", packageName)
+	}
+
+	return match[1], nil
+}
+
+// tomlArrayTablePackageResolver extracts a "version" key from a
+// `[[package]]`/`[[<section>]]`-style TOML array of tables whose `name`
+// entry matches packageName. It covers Cargo.lock and poetry.lock without
+// pulling in a full TOML parser.
+type tomlArrayTablePackageResolver struct {
+	fileName string
+	section  string
+}
+
+var tomlPackageRe = regexp.MustCompile(`\[\[([\w.-]+)]]((?:[^\n]|\n+[^\[\n])*)`)
+var tomlFieldRe = regexp.MustCompile(`(?m)^\s*(name|version)\s*=\s*"([^"]+)"`)
+
+func (r tomlArrayTablePackageResolver) Version(env runtime.Environment, packageName string) (string, error) {
+	if !env.HasFiles(r.fileName) {
+		return "", fmt.Errorf("%s not found", r.fileName)
+	}
+
+	content := env.FileContent(filepath.Join(env.Pwd(), r.fileName))
+
+	for _, block := range tomlPackageRe.FindAllStringSubmatch(content, -1) {
+		if block[1] != r.section {
+			continue
+		}
+
+		fields := map[string]string{}
+
+		for _, match := range tomlFieldRe.FindAllStringSubmatch(block[2], -1) {
+			fields[match[1]] = match[2]
+		}
+
+		if fields["name"] == packageName && fields["version"] != "" {
+			return fields["version"], nil
+		}
+	}
+
+	return "", fmt.Errorf("package %s not found in %s", packageName, r.fileName)
+}
+
+// rustPackageResolver resolves a crate's locked version from Cargo.lock.
+type rustPackageResolver struct{}
+
+func (rustPackageResolver) Version(env runtime.Environment, packageName string) (string, error) {
+	return tomlArrayTablePackageResolver{fileName: "Cargo.lock", section: "package"}.Version(env, packageName)
+}
+
+// pythonPoetryResolver resolves a dependency's locked version from poetry.lock.
+type pythonPoetryResolver struct{}
+
+func (pythonPoetryResolver) Version(env runtime.Environment, packageName string) (string, error) {
+	return tomlArrayTablePackageResolver{fileName: "poetry.lock", section: "package"}.Version(env, packageName)
+}
+
+// pythonPipfileResolver resolves a dependency's locked version from Pipfile.lock.
+type pythonPipfileResolver struct{}
+
+func (pythonPipfileResolver) Version(env runtime.Environment, packageName string) (string, error) {
+	if !env.HasFiles("Pipfile.lock") {
+		return "", fmt.Errorf("Pipfile.lock not found")
+	}
+
+	content := env.FileContent(filepath.Join(env.Pwd(), "Pipfile.lock"))
+
+	var lockfile struct {
+		Default map[string]struct {
+			Version string `json:"version"`
+		} `json:"default"`
+		Develop map[string]struct {
+			Version string `json:"version"`
+		} `json:"develop"`
+	}
+
+	if err := json.Unmarshal([]byte(content), &lockfile); err != nil {
+		return "", err
+	}
+
+	if entry, ok := lockfile.Default[packageName]; ok {
+		return trimPinPrefix(entry.Version), nil
+	}
+
+	if entry, ok := lockfile.Develop[packageName]; ok {
+		return trimPinPrefix(entry.Version), nil
+	}
+
+	return "", fmt.Errorf("package %s not found in Pipfile.lock", packageName)
+}
+
+// pythonPyprojectResolver resolves a dependency's declared constraint from a
+// Poetry-managed pyproject.toml's [tool.poetry.dependencies] table, with any
+// leading "=", "^" or "~" removed (so "^5.0.1" yields "5.0.1").
+type pythonPyprojectResolver struct{}
+
+var inlineTomlVersionRe = regexp.MustCompile(`version\s*=\s*"([^"]+)"`)
+
+func (pythonPyprojectResolver) Version(env runtime.Environment, packageName string) (string, error) {
+	if !env.HasFiles("pyproject.toml") {
+		return "", fmt.Errorf("pyproject.toml not found")
+	}
+
+	content := env.FileContent(filepath.Join(env.Pwd(), "pyproject.toml"))
+
+	versions := parsePoetryDependencies(content)
+
+	version, ok := versions[packageName]
+	if !ok {
+		return "", fmt.Errorf("package %s not found in pyproject.toml", packageName)
+	}
+
+	return trimPinPrefix(version), nil
+}
+
+// parsePoetryDependencies extracts the `[tool.poetry.dependencies]` table of
+// a pyproject.toml: `name = "^1.2.3"` and `name = { version = "^1.2.3", ... }`
+// entries.
+func parsePoetryDependencies(content string) map[string]string {
+	versions := make(map[string]string)
+
+	inSection := false
+
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			inSection = line == "[tool.poetry.dependencies]"
+			continue
+		}
+
+		if !inSection {
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+
+		key = strings.Trim(strings.TrimSpace(key), `"`)
+		value = strings.TrimSpace(value)
+
+		if strings.HasPrefix(value, "{") {
+			if m := inlineTomlVersionRe.FindStringSubmatch(value); m != nil {
+				value = m[1]
+			}
+		}
+
+		value = strings.Trim(value, `"`)
+
+		if value != "" {
+			versions[key] = value
+		}
+	}
+
+	return versions
+}
+
+// pythonRequirementsResolver resolves a pinned ("==") dependency version
+// from a requirements.txt file.
+type pythonRequirementsResolver struct{}
+
+var requirementsPinRe = regexp.MustCompile(`(?m)^\s*([\w.-]+)\s*==\s*([^\s#;]+)`)
+
+func (pythonRequirementsResolver) Version(env runtime.Environment, packageName string) (string, error) {
+	if !env.HasFiles("requirements.txt") {
+		return "", fmt.Errorf("requirements.txt not found")
+	}
+
+	content := env.FileContent(filepath.Join(env.Pwd(), "requirements.txt"))
+
+	for _, match := range requirementsPinRe.FindAllStringSubmatch(content, -1) {
+		if match[1] == packageName {
+			return match[2], nil
+		}
+	}
+
+	return "", fmt.Errorf("package %s is not pinned in requirements.txt", packageName)
+}
+
+// goModuleResolver resolves a module's required version from go.mod,
+// falling back to go.sum for transitive dependencies go.mod doesn't list
+// directly.
+type goModuleResolver struct{}
+
+var goRequireRe = regexp.MustCompile(`(?m)^\s*([^\s]+)\s+(v[^\s]+)`)
+
+// goSumRe matches a go.sum module's hash line (as opposed to its
+// "/go.mod" companion line, which pins the dependency's own go.mod hash
+// rather than its source).
+var goSumRe = regexp.MustCompile(`(?m)^(\S+)\s+(v[^\s/]+)\s+h1:`)
+
+func (goModuleResolver) Version(env runtime.Environment, packageName string) (string, error) {
+	if env.HasFiles("go.mod") {
+		content := env.FileContent(filepath.Join(env.Pwd(), "go.mod"))
+
+		for _, match := range goRequireRe.FindAllStringSubmatch(content, -1) {
+			if match[1] == packageName {
+				return match[2], nil
+			}
+		}
+	}
+
+	if env.HasFiles("go.sum") {
+		content := env.FileContent(filepath.Join(env.Pwd(), "go.sum"))
+
+		for _, match := range goSumRe.FindAllStringSubmatch(content, -1) {
+			if match[1] == packageName {
+				return match[2], nil
+			}
+		}
+	}
+
+	return "", fmt.Errorf("module %s not found in go.mod or go.sum", packageName)
+}
+
+// rubyBundlerResolver resolves a gem's locked version from Gemfile.lock.
+type rubyBundlerResolver struct{}
+
+var rubyGemRe = regexp.MustCompile(`(?m)^\s{4}([\w.-]+) \(([^)]+)\)`)
+
+func (rubyBundlerResolver) Version(env runtime.Environment, packageName string) (string, error) {
+	if !env.HasFiles("Gemfile.lock") {
+		return "", fmt.Errorf("Gemfile.lock not found")
+	}
+
+	content := env.FileContent(filepath.Join(env.Pwd(), "Gemfile.lock"))
+
+	for _, match := range rubyGemRe.FindAllStringSubmatch(content, -1) {
+		if match[1] == packageName {
+			return match[2], nil
+		}
+	}
+
+	return "", fmt.Errorf("gem %s not found in Gemfile.lock", packageName)
+}
+
+// phpComposerResolver resolves a package's locked version from composer.lock.
+type phpComposerResolver struct{}
+
+func (phpComposerResolver) Version(env runtime.Environment, packageName string) (string, error) {
+	if !env.HasFiles("composer.lock") {
+		return "", fmt.Errorf("composer.lock not found")
+	}
+
+	content := env.FileContent(filepath.Join(env.Pwd(), "composer.lock"))
+
+	var lockfile struct {
+		Packages []struct {
+			Name    string `json:"name"`
+			Version string `json:"version"`
+		} `json:"packages"`
+	}
+
+	if err := json.Unmarshal([]byte(content), &lockfile); err != nil {
+		return "", err
+	}
+
+	for _, pkg := range lockfile.Packages {
+		if pkg.Name == packageName {
+			return strings.TrimPrefix(trimPinPrefix(pkg.Version), "v"), nil
+		}
+	}
+
+	return "", fmt.Errorf("package %s not found in composer.lock", packageName)
+}
+
+func trimPinPrefix(version string) string {
+	for len(version) > 0 && (version[0] == '=' || version[0] == '^' || version[0] == '~') {
+		version = version[1:]
+	}
+
+	return version
+}
@@ -0,0 +1,365 @@
+package segments
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/jandedobbeleer/oh-my-posh/src/properties"
+	"github.com/jandedobbeleer/oh-my-posh/src/runtime/mock"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRustPackageResolver(t *testing.T) {
+	cases := []struct {
+		Case       string
+		CargoLock  string
+		Version    string
+		ShouldFail bool
+		NoFile     bool
+	}{
+		{
+			Case: "found",
+			CargoLock: "[[package]]\n" +
+				"name = \"serde\"\n" +
+				"version = \"1.0.197\"\n" +
+				"source = \"registry+https://github.com/rust-lang/crates.io-index\"\n" +
+				"\n" +
+				"[[package]]\n" +
+				"name = \"other\"\n" +
+				"version = \"0.1.0\"\n",
+			Version: "1.0.197",
+		},
+		{Case: "no file", NoFile: true, ShouldFail: true},
+		{Case: "missing package", CargoLock: "[[package]]\nname = \"other\"\nversion = \"0.1.0\"\n", ShouldFail: true},
+		{Case: "malformed", CargoLock: "not even toml", ShouldFail: true},
+	}
+
+	for _, tc := range cases {
+		env := new(mock.Environment)
+		env.On("Pwd").Return("/project")
+		env.On("HasFiles", "Cargo.lock").Return(!tc.NoFile)
+		env.On("FileContent", filepath.Join("/project", "Cargo.lock")).Return(tc.CargoLock)
+
+		got, err := (rustPackageResolver{}).Version(env, "serde")
+
+		if tc.ShouldFail {
+			assert.Error(t, err, tc.Case)
+			continue
+		}
+
+		assert.NoError(t, err, tc.Case)
+		assert.Equal(t, tc.Version, got, tc.Case)
+	}
+}
+
+func TestPythonPoetryResolver(t *testing.T) {
+	lock := "[[package]]\nname = \"django\"\nversion = \"5.0.1\"\n"
+
+	env := new(mock.Environment)
+	env.On("Pwd").Return("/project")
+	env.On("HasFiles", "poetry.lock").Return(true)
+	env.On("FileContent", filepath.Join("/project", "poetry.lock")).Return(lock)
+
+	got, err := (pythonPoetryResolver{}).Version(env, "django")
+	assert.NoError(t, err)
+	assert.Equal(t, "5.0.1", got)
+
+	_, err = (pythonPoetryResolver{}).Version(env, "flask")
+	assert.Error(t, err)
+}
+
+func TestPythonRequirementsResolver(t *testing.T) {
+	content := "# comment\ndjango==5.0.1\nrequests == 2.31.0\nflask>=2.0\n"
+
+	env := new(mock.Environment)
+	env.On("Pwd").Return("/project")
+	env.On("HasFiles", "requirements.txt").Return(true)
+	env.On("FileContent", filepath.Join("/project", "requirements.txt")).Return(content)
+
+	got, err := (pythonRequirementsResolver{}).Version(env, "django")
+	assert.NoError(t, err)
+	assert.Equal(t, "5.0.1", got)
+
+	_, err = (pythonRequirementsResolver{}).Version(env, "flask")
+	assert.Error(t, err, "flask is not pinned with ==")
+}
+
+func TestGoModuleResolver(t *testing.T) {
+	goMod := "module example.com/app\n\ngo 1.22\n\nrequire (\n\tgithub.com/stretchr/testify v1.9.0\n)\n"
+	goSum := "github.com/stretchr/testify v1.9.0/go.mod h1:abc=\n" +
+		"github.com/stretchr/testify v1.9.0 h1:def=\n" +
+		"github.com/pmezard/go-difflib v1.0.0 h1:ghi=\n"
+
+	cases := []struct {
+		Case       string
+		Package    string
+		GoMod      string
+		GoSum      string
+		HasGoMod   bool
+		HasGoSum   bool
+		Version    string
+		ShouldFail bool
+	}{
+		{Case: "found in go.mod", Package: "github.com/stretchr/testify", GoMod: goMod, HasGoMod: true, Version: "v1.9.0"},
+		{
+			Case: "found in go.sum only", Package: "github.com/pmezard/go-difflib",
+			GoMod: goMod, HasGoMod: true, GoSum: goSum, HasGoSum: true, Version: "v1.0.0",
+		},
+		{Case: "no go.mod or go.sum", ShouldFail: true},
+		{Case: "missing package", GoMod: goMod, HasGoMod: true, GoSum: goSum, HasGoSum: true, Package: "example.com/nope", ShouldFail: true},
+		{Case: "malformed go.mod, no go.sum", GoMod: "not even a go.mod", HasGoMod: true, Package: "github.com/stretchr/testify", ShouldFail: true},
+	}
+
+	for _, tc := range cases {
+		env := new(mock.Environment)
+		env.On("Pwd").Return("/project")
+		env.On("HasFiles", "go.mod").Return(tc.HasGoMod)
+		env.On("HasFiles", "go.sum").Return(tc.HasGoSum)
+		env.On("FileContent", filepath.Join("/project", "go.mod")).Return(tc.GoMod)
+		env.On("FileContent", filepath.Join("/project", "go.sum")).Return(tc.GoSum)
+
+		got, err := (goModuleResolver{}).Version(env, tc.Package)
+
+		if tc.ShouldFail {
+			assert.Error(t, err, tc.Case)
+			continue
+		}
+
+		assert.NoError(t, err, tc.Case)
+		assert.Equal(t, tc.Version, got, tc.Case)
+	}
+}
+
+func TestRubyBundlerResolver(t *testing.T) {
+	content := "GEM\n  remote: https://rubygems.org/\n  specs:\n    rails (7.1.3)\n    rake (13.1.0)\n"
+
+	cases := []struct {
+		Case       string
+		Content    string
+		HasFile    bool
+		Package    string
+		Version    string
+		ShouldFail bool
+	}{
+		{Case: "found", Content: content, HasFile: true, Package: "rails", Version: "7.1.3"},
+		{Case: "no file", ShouldFail: true, Package: "rails"},
+		{Case: "missing gem", Content: content, HasFile: true, Package: "sidekiq", ShouldFail: true},
+		{Case: "malformed", Content: "not a Gemfile.lock", HasFile: true, Package: "rails", ShouldFail: true},
+	}
+
+	for _, tc := range cases {
+		env := new(mock.Environment)
+		env.On("Pwd").Return("/project")
+		env.On("HasFiles", "Gemfile.lock").Return(tc.HasFile)
+		env.On("FileContent", filepath.Join("/project", "Gemfile.lock")).Return(tc.Content)
+
+		got, err := (rubyBundlerResolver{}).Version(env, tc.Package)
+
+		if tc.ShouldFail {
+			assert.Error(t, err, tc.Case)
+			continue
+		}
+
+		assert.NoError(t, err, tc.Case)
+		assert.Equal(t, tc.Version, got, tc.Case)
+	}
+}
+
+func TestPythonPipfileResolver(t *testing.T) {
+	lock := `{"default": {"django": {"version": "==5.0.1"}}, "develop": {"pytest": {"version": "==8.0.0"}}}`
+
+	cases := []struct {
+		Case       string
+		Content    string
+		HasFile    bool
+		Package    string
+		Version    string
+		ShouldFail bool
+	}{
+		{Case: "found in default", Content: lock, HasFile: true, Package: "django", Version: "5.0.1"},
+		{Case: "found in develop", Content: lock, HasFile: true, Package: "pytest", Version: "8.0.0"},
+		{Case: "no file", ShouldFail: true, Package: "django"},
+		{Case: "missing package", Content: lock, HasFile: true, Package: "flask", ShouldFail: true},
+		{Case: "malformed", Content: "not json", HasFile: true, Package: "django", ShouldFail: true},
+	}
+
+	for _, tc := range cases {
+		env := new(mock.Environment)
+		env.On("Pwd").Return("/project")
+		env.On("HasFiles", "Pipfile.lock").Return(tc.HasFile)
+		env.On("FileContent", filepath.Join("/project", "Pipfile.lock")).Return(tc.Content)
+
+		got, err := (pythonPipfileResolver{}).Version(env, tc.Package)
+
+		if tc.ShouldFail {
+			assert.Error(t, err, tc.Case)
+			continue
+		}
+
+		assert.NoError(t, err, tc.Case)
+		assert.Equal(t, tc.Version, got, tc.Case)
+	}
+}
+
+func TestPythonPyprojectResolver(t *testing.T) {
+	content := "[tool.poetry.dependencies]\n" +
+		"python = \"^3.11\"\n" +
+		"django = \"^5.0.1\"\n" +
+		"requests = { version = \"2.31.0\", optional = true }\n"
+
+	cases := []struct {
+		Case       string
+		Content    string
+		HasFile    bool
+		Package    string
+		Version    string
+		ShouldFail bool
+	}{
+		{Case: "plain entry", Content: content, HasFile: true, Package: "django", Version: "5.0.1"},
+		{Case: "inline table entry", Content: content, HasFile: true, Package: "requests", Version: "2.31.0"},
+		{Case: "no file", ShouldFail: true, Package: "django"},
+		{Case: "missing package", Content: content, HasFile: true, Package: "flask", ShouldFail: true},
+	}
+
+	for _, tc := range cases {
+		env := new(mock.Environment)
+		env.On("Pwd").Return("/project")
+		env.On("HasFiles", "pyproject.toml").Return(tc.HasFile)
+		env.On("FileContent", filepath.Join("/project", "pyproject.toml")).Return(tc.Content)
+
+		got, err := (pythonPyprojectResolver{}).Version(env, tc.Package)
+
+		if tc.ShouldFail {
+			assert.Error(t, err, tc.Case)
+			continue
+		}
+
+		assert.NoError(t, err, tc.Case)
+		assert.Equal(t, tc.Version, got, tc.Case)
+	}
+}
+
+func TestNodeYarnLockResolver(t *testing.T) {
+	content := "lodash@^4.17.21:\n  version \"4.17.21\"\n  resolved \"https://registry.yarnpkg.com/lodash\"\n\n" +
+		"react@^18.0.0, react@^18.2.0:\n  version \"18.2.0\"\n"
+
+	cases := []struct {
+		Case       string
+		Content    string
+		HasFile    bool
+		Package    string
+		Version    string
+		ShouldFail bool
+	}{
+		{Case: "found", Content: content, HasFile: true, Package: "lodash", Version: "4.17.21"},
+		{Case: "found with multiple ranges in header", Content: content, HasFile: true, Package: "react", Version: "18.2.0"},
+		{Case: "no file", ShouldFail: true, Package: "lodash"},
+		{Case: "missing package", Content: content, HasFile: true, Package: "vue", ShouldFail: true},
+	}
+
+	for _, tc := range cases {
+		env := new(mock.Environment)
+		env.On("Pwd").Return("/project")
+		env.On("HasFiles", "yarn.lock").Return(tc.HasFile)
+		env.On("FileContent", filepath.Join("/project", "yarn.lock")).Return(tc.Content)
+
+		got, err := nodeYarnLockVersion(env, tc.Package)
+
+		if tc.ShouldFail {
+			assert.Error(t, err, tc.Case)
+			continue
+		}
+
+		assert.NoError(t, err, tc.Case)
+		assert.Equal(t, tc.Version, got, tc.Case)
+	}
+}
+
+func TestNodePnpmLockResolver(t *testing.T) {
+	content := "packages:\n" +
+		"  /lodash@4.17.21:\n" +
+		"    resolution: {integrity: sha512-abc}\n" +
+		"  react@18.2.0:\n" +
+		"    resolution: {integrity: sha512-def}\n"
+
+	cases := []struct {
+		Case       string
+		Content    string
+		HasFile    bool
+		Package    string
+		Version    string
+		ShouldFail bool
+	}{
+		{Case: "v6 leading-slash style", Content: content, HasFile: true, Package: "lodash", Version: "4.17.21"},
+		{Case: "v9 bare style", Content: content, HasFile: true, Package: "react", Version: "18.2.0"},
+		{Case: "no file", ShouldFail: true, Package: "lodash"},
+		{Case: "missing package", Content: content, HasFile: true, Package: "vue", ShouldFail: true},
+	}
+
+	for _, tc := range cases {
+		env := new(mock.Environment)
+		env.On("Pwd").Return("/project")
+		env.On("HasFiles", "pnpm-lock.yaml").Return(tc.HasFile)
+		env.On("FileContent", filepath.Join("/project", "pnpm-lock.yaml")).Return(tc.Content)
+
+		got, err := nodePnpmLockVersion(env, tc.Package)
+
+		if tc.ShouldFail {
+			assert.Error(t, err, tc.Case)
+			continue
+		}
+
+		assert.NoError(t, err, tc.Case)
+		assert.Equal(t, tc.Version, got, tc.Case)
+	}
+}
+
+// TestNodePackageResolverFallsBackToLockfiles confirms nodePackageResolver
+// falls through to yarn.lock/pnpm-lock.yaml when node_modules isn't
+// installed, instead of only supporting the installed-package path.
+func TestNodePackageResolverFallsBackToLockfiles(t *testing.T) {
+	env := new(mock.Environment)
+	env.On("Pwd").Return("/project")
+	env.On("HasFilesInDir", filepath.Join("/project", "node_modules", "lodash"), "package.json").Return(false)
+	env.On("HasFiles", "yarn.lock").Return(false)
+	env.On("HasFiles", "pnpm-lock.yaml").Return(true)
+	env.On("FileContent", filepath.Join("/project", "pnpm-lock.yaml")).Return("packages:\n  lodash@4.17.21:\n")
+
+	got, err := (nodePackageResolver{}).Version(env, "lodash")
+	assert.NoError(t, err)
+	assert.Equal(t, "4.17.21", got)
+}
+
+func TestPHPComposerResolver(t *testing.T) {
+	content := `{"packages": [{"name": "symfony/console", "version": "v7.0.1"}]}`
+
+	env := new(mock.Environment)
+	env.On("Pwd").Return("/project")
+	env.On("HasFiles", "composer.lock").Return(true)
+	env.On("FileContent", filepath.Join("/project", "composer.lock")).Return(content)
+
+	got, err := (phpComposerResolver{}).Version(env, "symfony/console")
+	assert.NoError(t, err)
+	assert.Equal(t, "7.0.1", got)
+
+	_, err = (phpComposerResolver{}).Version(env, "missing/package")
+	assert.Error(t, err)
+}
+
+func TestResolvePackageStoresOnLanguage(t *testing.T) {
+	content := `{"packages": [{"name": "symfony/console", "version": "v7.0.1"}]}`
+
+	env := new(mock.Environment)
+	env.On("Pwd").Return("/project")
+	env.On("HasFiles", "composer.lock").Return(true)
+	env.On("FileContent", filepath.Join("/project", "composer.lock")).Return(content)
+
+	l := &language{}
+	l.Init(properties.Map{}, env)
+
+	got, err := l.resolvePackage(phpComposerResolver{}, "symfony/console")
+	assert.NoError(t, err)
+	assert.Equal(t, "7.0.1", got)
+	assert.Equal(t, "7.0.1", l.Packages["symfony/console"])
+}
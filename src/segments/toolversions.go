@@ -0,0 +1,282 @@
+package segments
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jandedobbeleer/oh-my-posh/src/runtime"
+)
+
+// toolVersionsCacheTTL bounds how long a resolved pin set is kept in
+// env.Cache(); the cache key itself is content-derived (see
+// toolVersionsCacheKey), so this TTL is just a backstop, not what makes an
+// edited pin file visible.
+const toolVersionsCacheTTL = 30 * time.Minute
+
+const (
+	ManagerAsdf   = "asdf"
+	ManagerMise   = "mise"
+	ManagerLegacy = "legacy"
+)
+
+// legacyVersionFiles lists, in a fixed order (map iteration order isn't
+// stable enough for a deterministic cache key), the single-purpose pin file
+// each asdf/mise plugin converged on before .tool-versions existed.
+var legacyVersionFiles = []struct {
+	Name string
+	File string
+}{
+	{Name: "nodejs", File: ".nvmrc"},
+	{Name: "python", File: ".python-version"},
+	{Name: "ruby", File: ".ruby-version"},
+	{Name: "terraform", File: ".terraform-version"},
+	{Name: "golang", File: ".go-version"},
+}
+
+// toolVersionEntry is the resolved pin for a single tool, along with where
+// it came from so the prompt can render a manager badge.
+type toolVersionEntry struct {
+	Constraint string         `json:"constraint"`
+	Kind       ConstraintKind `json:"kind"`
+	File       string         `json:"file"`
+	Manager    string         `json:"manager"`
+}
+
+// pinFile is a single pin file found while walking from Pwd() to Home(),
+// along with its raw content.
+type pinFile struct {
+	Dir     string
+	File    string
+	Name    string // tool name, for legacy files; empty for .tool-versions/.mise.toml
+	Manager string
+	Content string
+}
+
+// toolVersions resolves every pinned tool visible from the current
+// directory up to $HOME. The environment abstraction doesn't expose file
+// mtimes, so the cache is keyed off the pin files' own content: editing a
+// .tool-versions/.mise.toml/legacy file changes the key immediately instead
+// of waiting out toolVersionsCacheTTL.
+func toolVersions(env runtime.Environment) map[string]toolVersionEntry {
+	files := collectPinFiles(env)
+
+	cacheKey := toolVersionsCacheKey(env.Pwd(), files)
+
+	if cached, ok := env.Cache().Get(cacheKey); ok {
+		var entries map[string]toolVersionEntry
+		if err := json.Unmarshal([]byte(cached), &entries); err == nil {
+			return entries
+		}
+	}
+
+	entries := parsePinFiles(files)
+
+	if data, err := json.Marshal(entries); err == nil {
+		env.Cache().Set(cacheKey, string(data), toolVersionsCacheTTL)
+	}
+
+	return entries
+}
+
+// toolVersionsCacheKey folds the content of every discovered pin file into
+// the key, so a stale cache entry can never be returned for a directory
+// whose pin files have since changed.
+func toolVersionsCacheKey(cwd string, files []pinFile) string {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(cwd))
+
+	for _, f := range files {
+		_, _ = h.Write([]byte(f.Dir))
+		_, _ = h.Write([]byte(f.File))
+		_, _ = h.Write([]byte(f.Content))
+	}
+
+	return fmt.Sprintf("toolversions_%x", h.Sum64())
+}
+
+// collectPinFiles walks from Pwd() to Home() (inclusive), reading every pin
+// file it finds, closest directory first; within a directory, .mise.toml
+// before .tool-versions before legacy files — the order parsePinFiles relies
+// on for precedence.
+func collectPinFiles(env runtime.Environment) []pinFile {
+	var files []pinFile
+
+	home := env.Home()
+	dir := env.Pwd()
+
+	for {
+		if env.HasFilesInDir(dir, ".mise.toml") {
+			path := filepath.Join(dir, ".mise.toml")
+			files = append(files, pinFile{Dir: dir, File: ".mise.toml", Manager: ManagerMise, Content: env.FileContent(path)})
+		}
+
+		if env.HasFilesInDir(dir, ".tool-versions") {
+			path := filepath.Join(dir, ".tool-versions")
+			files = append(files, pinFile{Dir: dir, File: ".tool-versions", Manager: ManagerAsdf, Content: env.FileContent(path)})
+		}
+
+		for _, legacy := range legacyVersionFiles {
+			if !env.HasFilesInDir(dir, legacy.File) {
+				continue
+			}
+
+			path := filepath.Join(dir, legacy.File)
+			files = append(files, pinFile{Dir: dir, File: legacy.File, Name: legacy.Name, Manager: ManagerLegacy, Content: env.FileContent(path)})
+		}
+
+		if dir == home {
+			break
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+
+		dir = parent
+	}
+
+	return files
+}
+
+// parsePinFiles turns the raw pin files collectPinFiles found into resolved
+// constraints. The first file in the slice to claim a tool name wins, which
+// — given collectPinFiles' append order — means the closest directory wins,
+// and within a directory .mise.toml beats .tool-versions beats legacy files.
+func parsePinFiles(files []pinFile) map[string]toolVersionEntry {
+	entries := make(map[string]toolVersionEntry)
+
+	add := func(name, raw, file, manager string) {
+		if _, exists := entries[name]; exists {
+			return
+		}
+
+		constraint, err := ParseConstraint(raw)
+		if err != nil {
+			return
+		}
+
+		entries[name] = toolVersionEntry{
+			Constraint: constraint.String(),
+			Kind:       constraint.Kind,
+			File:       file,
+			Manager:    manager,
+		}
+	}
+
+	for _, f := range files {
+		path := filepath.Join(f.Dir, f.File)
+
+		switch f.File {
+		case ".mise.toml":
+			for name, raw := range parseMiseToml(f.Content) {
+				add(name, raw, path, f.Manager)
+			}
+		case ".tool-versions":
+			for name, raw := range parseToolVersions(f.Content) {
+				add(name, raw, path, f.Manager)
+			}
+		default:
+			add(f.Name, strings.TrimSpace(f.Content), path, f.Manager)
+		}
+	}
+
+	return entries
+}
+
+// parseToolVersions parses a `.tool-versions` file: one "<name> <version>
+// [<version>...]" entry per line, the first listed version winning.
+func parseToolVersions(content string) map[string]string {
+	versions := make(map[string]string)
+
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+
+		versions[fields[0]] = fields[1]
+	}
+
+	return versions
+}
+
+// parseMiseToml extracts a minimal subset of mise.toml: a `[tools]` table
+// of `name = "version"` (or `name = { version = "..." }`) entries.
+func parseMiseToml(content string) map[string]string {
+	versions := make(map[string]string)
+
+	inTools := false
+
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			inTools = line == "[tools]"
+			continue
+		}
+
+		if !inTools {
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+
+		key = strings.Trim(strings.TrimSpace(key), `"`)
+		value = strings.TrimSpace(value)
+
+		if idx := strings.Index(value, "version"); strings.HasPrefix(value, "{") && idx >= 0 {
+			value = value[idx+len("version"):]
+			if eq := strings.Index(value, "="); eq >= 0 {
+				value = value[eq+1:]
+			}
+		}
+
+		value = strings.Trim(value, `"{} `)
+
+		if value != "" {
+			versions[key] = value
+		}
+	}
+
+	return versions
+}
+
+// matchesToolVersions builds a matchesVersionFile hook backed by the
+// toolversions subsystem for the given asdf/mise plugin name, e.g.
+// "nodejs" or "python". It also records ToolVersionsFile/ToolVersionsManager
+// on the language segment for display.
+func (l *language) matchesToolVersions(name string) matchesVersionFile {
+	return func() (*Constraint, bool) {
+		entry, ok := toolVersions(l.env)[name]
+		if !ok {
+			return nil, false
+		}
+
+		l.ToolVersionsFile = entry.File
+		l.ToolVersionsManager = entry.Manager
+
+		constraint, err := ParseConstraint(entry.Constraint)
+		if err != nil {
+			return nil, false
+		}
+
+		return constraint, true
+	}
+}
@@ -0,0 +1,176 @@
+package segments
+
+import (
+	"path/filepath"
+	"testing"
+
+	cache_ "github.com/jandedobbeleer/oh-my-posh/src/cache/mock"
+	"github.com/jandedobbeleer/oh-my-posh/src/properties"
+	"github.com/jandedobbeleer/oh-my-posh/src/runtime/mock"
+
+	"github.com/stretchr/testify/assert"
+	mock_ "github.com/stretchr/testify/mock"
+)
+
+// knownToolVersionFiles are every pin file collectPinFiles looks for in a
+// directory; tests stub all of them explicitly (defaulting to "absent") so
+// testify resolves each call unambiguously instead of via Anything.
+var knownToolVersionFiles = append([]string{".mise.toml", ".tool-versions"}, func() []string {
+	var files []string
+	for _, legacy := range legacyVersionFiles {
+		files = append(files, legacy.File)
+	}
+	return files
+}()...)
+
+// stubEmptyDir registers an "absent" result for every known pin file in dir.
+func stubEmptyDir(env *mock.Environment, dir string) {
+	for _, file := range knownToolVersionFiles {
+		env.On("HasFilesInDir", dir, file).Return(false)
+	}
+}
+
+func newToolVersionsEnv(home, cwd string) *mock.Environment {
+	env := new(mock.Environment)
+	env.On("Pwd").Return(cwd)
+	env.On("Home").Return(home)
+
+	stubEmptyDir(env, home)
+
+	if cwd != home {
+		stubEmptyDir(env, cwd)
+	}
+
+	return env
+}
+
+// stubFile overrides a single pin file in dir as present with the given
+// content. Safe to call more than once for the same dir/file (e.g. to
+// simulate a later edit), since it clears any earlier expectation first.
+func stubFile(env *mock.Environment, dir, file, content string) {
+	env.On("HasFilesInDir", dir, file).Unset()
+	env.On("HasFilesInDir", dir, file).Return(true)
+	env.On("FileContent", filepath.Join(dir, file)).Unset()
+	env.On("FileContent", filepath.Join(dir, file)).Return(content)
+}
+
+func newCacheMiss(env *mock.Environment) {
+	cache := &cache_.Cache{}
+	cache.On("Get", mock_.Anything).Return("", false)
+	cache.On("Set", mock_.Anything, mock_.Anything, mock_.Anything).Return(nil)
+	env.On("Cache").Return(cache)
+}
+
+func TestParseToolVersions(t *testing.T) {
+	content := "nodejs 20.11.1\npython 3.11.4\n# comment\nruby 3.3.0 3.2.2\n"
+	versions := parseToolVersions(content)
+
+	assert.Equal(t, "20.11.1", versions["nodejs"])
+	assert.Equal(t, "3.11.4", versions["python"])
+	assert.Equal(t, "3.3.0", versions["ruby"], "the first listed version wins")
+}
+
+func TestParseMiseToml(t *testing.T) {
+	content := "[settings]\nfoo = true\n\n[tools]\nnode = \"20\"\npython = { version = \"3.11\" }\n"
+	versions := parseMiseToml(content)
+
+	assert.Equal(t, "20", versions["node"])
+	assert.Equal(t, "3.11", versions["python"])
+}
+
+func TestToolVersionsClosestDirWins(t *testing.T) {
+	home := "/usr/home"
+	project := "/usr/home/project"
+
+	env := newToolVersionsEnv(home, project)
+	stubFile(env, project, ".tool-versions", "nodejs 20.11.1\n")
+	stubFile(env, home, ".tool-versions", "nodejs 18.0.0\npython 3.11.4\n")
+	newCacheMiss(env)
+
+	entries := toolVersions(env)
+
+	assert.Equal(t, "20.11.1", entries["nodejs"].Constraint, "the closer .tool-versions wins")
+	assert.Equal(t, "3.11.4", entries["python"].Constraint, "inherited from the home directory")
+	assert.Equal(t, ManagerAsdf, entries["nodejs"].Manager)
+}
+
+func TestToolVersionsMiseOverridesToolVersionsInSameDir(t *testing.T) {
+	home := "/usr/home"
+	project := "/usr/home/project"
+
+	env := newToolVersionsEnv(home, project)
+	stubFile(env, project, ".tool-versions", "nodejs 18.0.0\n")
+	stubFile(env, project, ".mise.toml", "[tools]\nnodejs = \"20.11.1\"\n")
+	newCacheMiss(env)
+
+	entries := toolVersions(env)
+
+	assert.Equal(t, "20.11.1", entries["nodejs"].Constraint)
+	assert.Equal(t, ManagerMise, entries["nodejs"].Manager)
+}
+
+// TestToolVersionsCacheReuse confirms that a cache hit returns the cached
+// entries without re-parsing/re-marshaling them (no Set call). Unlike the
+// durable, wall-clock-TTL cache this replaced, the key is derived from pin
+// file content, so a hit still requires reading those files — see
+// TestToolVersionsCacheKeyChangesWithContent for the freshness guarantee.
+func TestToolVersionsCacheReuse(t *testing.T) {
+	home := "/usr/home"
+	project := "/usr/home/project"
+
+	env := newToolVersionsEnv(home, project)
+
+	cache := &cache_.Cache{}
+	cache.On("Get", mock_.Anything).Return(`{"nodejs":{"constraint":"20.11.1","kind":"exact","file":"/cache/.tool-versions","manager":"asdf"}}`, true)
+	env.On("Cache").Return(cache)
+
+	entries := toolVersions(env)
+
+	assert.Equal(t, "20.11.1", entries["nodejs"].Constraint)
+	cache.AssertNotCalled(t, "Set", mock_.Anything, mock_.Anything, mock_.Anything)
+}
+
+// TestToolVersionsCacheKeyChangesWithContent is the actual fix under test:
+// the cache key must change the instant a pin file's content changes, so an
+// edit (e.g. `nvm use 20` rewriting .tool-versions) is never masked by a
+// stale cache entry for up to toolVersionsCacheTTL.
+func TestToolVersionsCacheKeyChangesWithContent(t *testing.T) {
+	before := []pinFile{{Dir: "/project", File: ".tool-versions", Content: "nodejs 18.0.0\n"}}
+	after := []pinFile{{Dir: "/project", File: ".tool-versions", Content: "nodejs 20.11.1\n"}}
+
+	beforeKey := toolVersionsCacheKey("/project", before)
+	afterKey := toolVersionsCacheKey("/project", after)
+	beforeKeyAgain := toolVersionsCacheKey("/project", before)
+
+	assert.NotEqual(t, beforeKey, afterKey, "editing a pin file must change the cache key")
+	assert.Equal(t, beforeKey, beforeKeyAgain, "identical pin file state must hash to the same key")
+}
+
+func TestLanguageFallsBackToToolVersions(t *testing.T) {
+	resetVersionCommandCache()
+
+	home := "/usr/home"
+	project := "/usr/home/project"
+
+	env := newToolVersionsEnv(home, project)
+	stubFile(env, project, ".tool-versions", "nodejs 18.0.0\n")
+	newCacheMiss(env)
+
+	env.On("HasCommand", "node").Return(true)
+	env.On("RunCommand", "node", []string{"--version"}).Return("20.11.1", nil)
+	env.On("HasFiles", uni).Return(true)
+
+	l := &language{
+		extensions: []string{uni},
+		commands: []*cmd{
+			{executable: "node", args: []string{"--version"}, regex: "(?P<version>.*)"},
+		},
+		toolVersionName: "nodejs",
+	}
+	l.Init(properties.Map{}, env)
+
+	assert.True(t, l.Enabled())
+	assert.Equal(t, "18.0.0", l.Expected)
+	assert.Equal(t, ManagerAsdf, l.ToolVersionsManager)
+	assert.True(t, l.Mismatch, "project pins 18.0.0 but node reports 20.11.1")
+}